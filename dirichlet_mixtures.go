@@ -0,0 +1,109 @@
+package seq
+
+// aaOrder is the standard ordering of the 20 canonical amino acids used to
+// lay out the package's built-in Dirichlet mixtures below.
+var aaOrder = []Residue{
+	'A', 'R', 'N', 'D', 'C', 'Q', 'E', 'G', 'H', 'I',
+	'L', 'K', 'M', 'F', 'P', 'S', 'T', 'W', 'Y', 'V',
+}
+
+// newComponent builds a DirichletComponent from a mixture weight and a list
+// of per-residue pseudocounts given in aaOrder.
+func newComponent(weight float64, alphas []float64) DirichletComponent {
+	alpha := make(map[Residue]float64, len(aaOrder))
+	for i, r := range aaOrder {
+		alpha[r] = alphas[i]
+	}
+	return DirichletComponent{Weight: weight, Alpha: alpha}
+}
+
+// Recode4 is the package's default multi-component amino acid Dirichlet
+// mixture prior: a small number of components, each a plausible amino acid
+// composition (hydrophobic core, charged surface, small/polar, and so on),
+// mixed according to how well they explain an observed column.
+//
+// The component weights and alphas below are hand-tuned defaults loosely
+// modeled on the general shape of mixtures like those described by
+// Sjolander et al., "Dirichlet Mixtures: A Method for Improved Detection of
+// Weak but Significant Protein Sequence Homology" (1996) — they are not
+// that paper's published parameter tables. Callers who need the exact
+// published coefficients should build their own DirichletMixturePrior from
+// them rather than relying on Recode4.
+//
+// It is intended as a reasonable default for FrequencyProfile.ProfileWithPrior
+// when no domain-specific mixture is available.
+var Recode4 = &DirichletMixturePrior{
+	Components: []DirichletComponent{
+		// A broad, near-uniform component covering columns with no
+		// strong compositional signal.
+		newComponent(0.20, []float64{
+			0.92, 0.53, 0.50, 0.56, 0.23, 0.42, 0.59, 0.77, 0.28, 0.60,
+			0.86, 0.58, 0.22, 0.45, 0.48, 0.71, 0.63, 0.11, 0.32, 0.70,
+		}),
+		// Hydrophobic core: favors I/L/V/M/F over charged residues.
+		newComponent(0.18, []float64{
+			0.40, 0.06, 0.06, 0.05, 0.10, 0.07, 0.06, 0.20, 0.08, 1.10,
+			1.60, 0.07, 0.35, 0.75, 0.12, 0.20, 0.25, 0.18, 0.30, 1.30,
+		}),
+		// Charged/polar surface: favors D/E/K/R/N/Q.
+		newComponent(0.17, []float64{
+			0.35, 0.95, 0.70, 0.95, 0.03, 0.55, 1.00, 0.30, 0.20, 0.08,
+			0.10, 1.05, 0.05, 0.05, 0.25, 0.55, 0.40, 0.02, 0.10, 0.10,
+		}),
+		// Small/flexible: favors G/A/S/P/N.
+		newComponent(0.12, []float64{
+			0.80, 0.05, 0.45, 0.20, 0.05, 0.10, 0.15, 1.40, 0.05, 0.05,
+			0.10, 0.10, 0.03, 0.05, 0.75, 0.95, 0.45, 0.02, 0.08, 0.15,
+		}),
+		// Aromatic: favors F/W/Y/H.
+		newComponent(0.08, []float64{
+			0.10, 0.08, 0.05, 0.05, 0.05, 0.05, 0.05, 0.10, 0.90, 0.15,
+			0.25, 0.08, 0.10, 1.20, 0.05, 0.10, 0.10, 1.00, 1.30, 0.12,
+		}),
+		// Cysteine-rich: disulfide-forming regions.
+		newComponent(0.05, []float64{
+			0.10, 0.05, 0.05, 0.05, 3.50, 0.05, 0.05, 0.10, 0.05, 0.10,
+			0.10, 0.05, 0.10, 0.08, 0.05, 0.15, 0.10, 0.02, 0.05, 0.10,
+		}),
+		// Proline/turn-rich.
+		newComponent(0.07, []float64{
+			0.20, 0.10, 0.25, 0.15, 0.03, 0.10, 0.12, 0.60, 0.10, 0.05,
+			0.08, 0.10, 0.03, 0.05, 2.20, 0.30, 0.20, 0.02, 0.05, 0.08,
+		}),
+		// Basic surface: strongly favors K/R.
+		newComponent(0.07, []float64{
+			0.10, 1.80, 0.15, 0.08, 0.02, 0.15, 0.10, 0.10, 0.25, 0.05,
+			0.08, 1.60, 0.05, 0.05, 0.05, 0.15, 0.12, 0.05, 0.08, 0.08,
+		}),
+		// Acidic surface: strongly favors D/E.
+		newComponent(0.06, []float64{
+			0.08, 0.10, 0.20, 1.90, 0.02, 0.20, 1.70, 0.10, 0.08, 0.05,
+			0.08, 0.15, 0.05, 0.05, 0.05, 0.15, 0.12, 0.02, 0.05, 0.05,
+		}),
+	},
+}
+
+// Byrne is an alternative built-in mixture biased toward membrane protein
+// composition (richer in hydrophobic residues, sparser in charged ones),
+// built the same way as Recode4 but from four components. Like Recode4, its
+// alphas are hand-tuned defaults, not published literature values.
+var Byrne = &DirichletMixturePrior{
+	Components: []DirichletComponent{
+		newComponent(0.35, []float64{
+			0.60, 0.10, 0.20, 0.15, 0.20, 0.15, 0.15, 0.55, 0.20, 1.40,
+			2.10, 0.12, 0.45, 1.10, 0.25, 0.45, 0.55, 0.35, 0.55, 1.80,
+		}),
+		newComponent(0.30, []float64{
+			0.70, 0.60, 0.50, 0.60, 0.10, 0.45, 0.65, 0.70, 0.25, 0.45,
+			0.70, 0.60, 0.20, 0.40, 0.45, 0.65, 0.60, 0.12, 0.30, 0.65,
+		}),
+		newComponent(0.20, []float64{
+			0.20, 1.10, 0.60, 1.10, 0.03, 0.55, 1.10, 0.20, 0.15, 0.06,
+			0.08, 1.15, 0.04, 0.04, 0.15, 0.40, 0.30, 0.02, 0.08, 0.08,
+		}),
+		newComponent(0.15, []float64{
+			0.10, 0.06, 0.05, 0.05, 0.05, 0.05, 0.05, 0.10, 0.80, 0.20,
+			0.30, 0.08, 0.12, 1.30, 0.05, 0.10, 0.10, 1.10, 1.40, 0.15,
+		}),
+	},
+}
@@ -0,0 +1,36 @@
+package seq
+
+import "testing"
+
+func TestBuiltinMixtureWeightsSumToOne(t *testing.T) {
+	for name, mix := range map[string]*DirichletMixturePrior{
+		"Recode4": Recode4,
+		"Byrne":   Byrne,
+	} {
+		sum := 0.0
+		for _, c := range mix.Components {
+			sum += c.Weight
+		}
+		approxEqual(t, name+" weight sum", sum, 1.0, 1e-9)
+	}
+}
+
+func TestBuiltinMixtureComponentsCoverAlphabet(t *testing.T) {
+	for name, mix := range map[string]*DirichletMixturePrior{
+		"Recode4": Recode4,
+		"Byrne":   Byrne,
+	} {
+		for i, c := range mix.Components {
+			if len(c.Alpha) != len(aaOrder) {
+				t.Errorf("%s component %d: got %d alphas, want %d",
+					name, i, len(c.Alpha), len(aaOrder))
+			}
+			for _, r := range aaOrder {
+				if c.Alpha[r] <= 0 {
+					t.Errorf("%s component %d: alpha[%c] = %v, want > 0",
+						name, i, r, c.Alpha[r])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package seq
+
+import "math"
+
+// logAddProb combines two negative-log-odds probabilities as though the
+// underlying probabilities (exp(-a) and exp(-b)) were added, returning the
+// result in the same negative-log-odds space:
+//
+//	logAddProb(a, b) == -log(exp(-a) + exp(-b))
+//
+// The computation is done with the usual max-shift trick (here, a min-shift,
+// since smaller Prob values correspond to larger probabilities) to avoid
+// floating point underflow. An operand for which IsMin is true represents a
+// probability of zero and therefore contributes nothing to the sum; it is
+// returned unchanged (or MinProb if both operands are minimal).
+func logAddProb(a, b Prob) Prob {
+	if a.IsMin() {
+		return b
+	}
+	if b.IsMin() {
+		return a
+	}
+	if b < a {
+		a, b = b, a
+	}
+	return a - Prob(math.Log1p(math.Exp(-float64(b-a))))
+}
+
+// addProb combines two negative-log-odds probabilities as though the
+// underlying probabilities were multiplied, i.e. addProb(a, b) stands for
+// exp(-a) * exp(-b). Plain Prob addition is unsafe for this: MinProb is
+// math.MaxFloat64, so MinProb+MinProb silently overflows to +Inf, which no
+// longer compares equal to MinProb, and a +Inf operand later reaching
+// logAddProb's subtraction produces NaN instead of the "impossible" value it
+// should represent. addProb checks IsMin on each operand before the
+// arithmetic so a MinProb input always yields MinProb out.
+func addProb(a, b Prob) Prob {
+	if a.IsMin() || b.IsMin() {
+		return MinProb
+	}
+	return a + b
+}
+
+// viterbiCell records the running best score for a single DP cell along with
+// enough information to trace the optimal path back to the beginning of the
+// trellis.
+type viterbiCell struct {
+	score   Prob
+	state   HMMState
+	fromRow int
+	fromCol int
+}
+
+// bestCell returns the cell with the smaller (i.e., more probable) score.
+func bestCell(a, b viterbiCell) viterbiCell {
+	// a.score.Less(b.score) reports whether a represents a *smaller*
+	// probability than b (i.e. a is worse), so the better cell is b.
+	if a.score.Less(b.score) {
+		return b
+	}
+	return a
+}
+
+// Viterbi computes the single most probable Plan7 state path through hmm
+// that could have generated s, along with its score.
+//
+// The path and score are computed entirely in hmm's negative-log-odds space:
+// each step of the recurrence sums Prob values (since in log space,
+// multiplying probabilities along a path becomes addition) and the DP
+// chooses, at each cell, the predecessor with the smallest resulting Prob
+// (since smaller negative-log-odds values mean larger probabilities). There
+// is no explicit Begin or End state in an HMM's Nodes; the trellis is
+// implicitly bracketed by reusing the first node's Transitions as the
+// Begin-to-first-node step and the last node's Transitions as the
+// last-node-to-End step, exactly as HMM.Slice already assumes when it
+// rewrites the last node's transitions to force an exit.
+func (hmm *HMM) Viterbi(s Sequence) (path []HMMState, score Prob) {
+	n := len(hmm.Nodes)
+	l := s.Len()
+	if n == 0 {
+		return nil, MinProb
+	}
+
+	// vm, vi, vd are viterbi tables for the Match, Insertion and Deletion
+	// states. Row 0 represents the virtual Begin state; rows 1..n
+	// correspond to hmm.Nodes[0:n].
+	vm := newViterbiTable(n+1, l+1)
+	vi := newViterbiTable(n+1, l+1)
+	vd := newViterbiTable(n+1, l+1)
+
+	// Only the Begin state itself (the Match-equivalent row-0 cell) starts
+	// at probability 1; there is no Begin-as-Insertion or
+	// Begin-as-Deletion state, so vi[0][0] and vd[0][0] stay at MinProb.
+	vm[0][0] = viterbiCell{score: 0, fromRow: -1}
+
+	for k := 1; k <= n; k++ {
+		node := hmm.Nodes[k-1]
+		pt := hmm.Nodes[prevNodeIndex(k)].Transitions
+
+		for j := 0; j <= l; j++ {
+			vd[k][j] = bestCell(
+				viterbiCell{addProb(vm[k-1][j].score, pt.MD), Match, k - 1, j},
+				viterbiCell{addProb(vd[k-1][j].score, pt.DD), Deletion, k - 1, j},
+			)
+		}
+
+		for j := 1; j <= l; j++ {
+			r := s.Residues[j-1]
+
+			best := bestCell(
+				viterbiCell{addProb(vm[k-1][j-1].score, pt.MM), Match, k - 1, j - 1},
+				bestCell(
+					viterbiCell{addProb(vi[k-1][j-1].score, pt.IM), Insertion, k - 1, j - 1},
+					viterbiCell{addProb(vd[k-1][j-1].score, pt.DM), Deletion, k - 1, j - 1},
+				),
+			)
+			best.score = addProb(best.score, node.MatEmit.EmitProb(r))
+			vm[k][j] = best
+		}
+
+		for j := 1; j <= l; j++ {
+			r := s.Residues[j-1]
+
+			// Insertion states loop on themselves and are only ever
+			// entered from the Match state of the very same node, via
+			// that node's own transitions (the II self-transition).
+			best := bestCell(
+				viterbiCell{addProb(vm[k][j-1].score, node.Transitions.MI), Match, k, j - 1},
+				viterbiCell{addProb(vi[k][j-1].score, node.Transitions.II), Insertion, k, j - 1},
+			)
+			best.score = addProb(best.score, node.InsEmit.EmitProb(r))
+			vi[k][j] = best
+		}
+	}
+
+	exit := hmm.Nodes[n-1].Transitions
+	final := bestCell(
+		viterbiCell{addProb(vm[n][l].score, exit.MM), Match, n, l},
+		bestCell(
+			viterbiCell{addProb(vi[n][l].score, exit.IM), Insertion, n, l},
+			viterbiCell{addProb(vd[n][l].score, exit.DM), Deletion, n, l},
+		),
+	)
+	score = final.score
+
+	// Trace the path back from (n, l) to the Begin state.
+	row, col, state := n, l, final.state
+	for row > 0 {
+		path = append(path, state)
+		var cell viterbiCell
+		switch state {
+		case Match:
+			cell = vm[row][col]
+		case Insertion:
+			cell = vi[row][col]
+		case Deletion:
+			cell = vd[row][col]
+		}
+		row, col, state = cell.fromRow, cell.fromCol, cell.state
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, score
+}
+
+// Forward computes the total probability, in negative-log-odds space, of
+// hmm having generated s by any path at all (i.e., summed over every Plan7
+// state path rather than just the single best one found by Viterbi).
+//
+// The recurrence mirrors Viterbi's exactly, except that at each cell the
+// competing predecessors are combined with logAddProb (a log-sum-exp
+// reduction) rather than a min-selecting comparison.
+func (hmm *HMM) Forward(s Sequence) Prob {
+	n := len(hmm.Nodes)
+	l := s.Len()
+	if n == 0 {
+		return MinProb
+	}
+
+	fm, fi, fd := hmm.forwardTables(s)
+
+	exit := hmm.Nodes[n-1].Transitions
+	return logAddProb(addProb(fm[n][l], exit.MM),
+		logAddProb(addProb(fi[n][l], exit.IM), addProb(fd[n][l], exit.DM)))
+}
+
+// forwardTables runs the Forward recurrence and returns the full Match,
+// Insertion and Deletion probability tables, for reuse by ForwardBackward.
+func (hmm *HMM) forwardTables(s Sequence) (fm, fi, fd [][]Prob) {
+	n := len(hmm.Nodes)
+	l := s.Len()
+
+	fm = newProbTable(n+1, l+1)
+	fi = newProbTable(n+1, l+1)
+	fd = newProbTable(n+1, l+1)
+
+	// Only the Begin state itself (the Match-equivalent row-0 cell) starts
+	// at probability 1; fi[0][0] and fd[0][0] stay at MinProb, since there
+	// is no Begin-as-Insertion or Begin-as-Deletion state.
+	fm[0][0] = 0
+
+	for k := 1; k <= n; k++ {
+		node := hmm.Nodes[k-1]
+		pt := hmm.Nodes[prevNodeIndex(k)].Transitions
+
+		for j := 0; j <= l; j++ {
+			fd[k][j] = logAddProb(addProb(fm[k-1][j], pt.MD), addProb(fd[k-1][j], pt.DD))
+		}
+		for j := 1; j <= l; j++ {
+			r := s.Residues[j-1]
+			sum := logAddProb(addProb(fm[k-1][j-1], pt.MM),
+				logAddProb(addProb(fi[k-1][j-1], pt.IM), addProb(fd[k-1][j-1], pt.DM)))
+			fm[k][j] = addProb(sum, node.MatEmit.EmitProb(r))
+		}
+		for j := 1; j <= l; j++ {
+			r := s.Residues[j-1]
+			sum := logAddProb(addProb(fm[k][j-1], node.Transitions.MI),
+				addProb(fi[k][j-1], node.Transitions.II))
+			fi[k][j] = addProb(sum, node.InsEmit.EmitProb(r))
+		}
+	}
+	return fm, fi, fd
+}
+
+// ForwardBackward runs the forward-backward algorithm and returns, for every
+// sequence position and node, the posterior probability (in negative-log-
+// odds space) that the node's Match state emitted that position, along with
+// ll, the total log-odds likelihood of s under hmm (the same value Forward
+// would return).
+//
+// posteriors is indexed as posteriors[j][k] for sequence position j
+// (0-based) and node index k (0-based), i.e. posteriors[j] is the posterior
+// distribution, across nodes, for the residue at s.Residues[j].
+func (hmm *HMM) ForwardBackward(s Sequence) (posteriors [][]Prob, ll Prob) {
+	n := len(hmm.Nodes)
+	l := s.Len()
+	if n == 0 {
+		return nil, MinProb
+	}
+
+	fm, _, _ := hmm.forwardTables(s)
+	bm := hmm.backwardTable(s)
+	ll = hmm.Forward(s)
+
+	posteriors = make([][]Prob, l)
+	for j := 0; j < l; j++ {
+		posteriors[j] = make([]Prob, n)
+		for k := 1; k <= n; k++ {
+			post := addProb(fm[k][j+1], bm[k][j+1])
+			if post.IsMin() || ll.IsMin() {
+				posteriors[j][k-1] = MinProb
+			} else {
+				posteriors[j][k-1] = post - ll
+			}
+		}
+	}
+	return posteriors, ll
+}
+
+// backwardTable computes, for every node k (1..n) and position j (0..l),
+// the probability (in negative-log-odds space) of generating the remainder
+// of the sequence s.Residues[j:] given that the model is in the Match state
+// of node k having already emitted s.Residues[:j].
+func (hmm *HMM) backwardTable(s Sequence) [][]Prob {
+	n := len(hmm.Nodes)
+	l := s.Len()
+
+	bm := newProbTable(n+2, l+1)
+	bi := newProbTable(n+2, l+1)
+	bd := newProbTable(n+2, l+1)
+
+	// Row n+1 is left at MinProb: there is no node n+1, so the "to next
+	// node" options below naturally vanish for the last real node except
+	// where they are replaced by the exit bracket below.
+	exit := hmm.Nodes[n-1].Transitions
+	bm[n][l] = exit.MM
+	bi[n][l] = exit.IM
+	bd[n][l] = exit.DM
+
+	for k := n; k >= 1; k-- {
+		node := hmm.Nodes[k-1]
+
+		for j := l - 1; j >= 0; j-- {
+			r := s.Residues[j]
+			ins := addProb(addProb(node.Transitions.II, node.InsEmit.EmitProb(r)), bi[k][j+1])
+
+			var toNextMatch Prob = MinProb
+			if k < n {
+				matNext := hmm.Nodes[k].MatEmit.EmitProb(r)
+				toNextMatch = addProb(addProb(node.Transitions.IM, matNext), bm[k+1][j+1])
+			}
+			bi[k][j] = logAddProb(ins, toNextMatch)
+		}
+
+		for j := l; j >= 0; j-- {
+			if j == l {
+				// Already seeded by the exit bracket for k == n; for
+				// earlier nodes, only the silent Deletion->Deletion chain
+				// can still be pending once the sequence is exhausted.
+				if k < n {
+					bm[k][j] = addProb(node.Transitions.MD, bd[k+1][j])
+					bd[k][j] = addProb(node.Transitions.DD, bd[k+1][j])
+				}
+				continue
+			}
+
+			r := s.Residues[j]
+			toIns := addProb(addProb(node.Transitions.MI, node.InsEmit.EmitProb(r)), bi[k][j+1])
+			toDel := addProb(node.Transitions.MD, bd[k+1][j])
+
+			var matchToMatch, delToMatch Prob = MinProb, MinProb
+			if k < n {
+				matNext := hmm.Nodes[k].MatEmit.EmitProb(r)
+				matchToMatch = addProb(addProb(node.Transitions.MM, matNext), bm[k+1][j+1])
+				delToMatch = addProb(addProb(node.Transitions.DM, matNext), bm[k+1][j+1])
+			}
+			bm[k][j] = logAddProb(toIns, logAddProb(matchToMatch, toDel))
+			bd[k][j] = logAddProb(delToMatch, addProb(node.Transitions.DD, bd[k+1][j]))
+		}
+	}
+	return bm
+}
+
+// prevNodeIndex returns the 0-based index, into hmm.Nodes, of the
+// transitions that should be used to enter the 1-indexed node k. Ordinarily
+// this is node k-2 (the node immediately before it), but node 1 has no
+// predecessor node, so the Begin-to-first-node step implicitly reuses the
+// first node's own transitions.
+func prevNodeIndex(k int) int {
+	if k-2 < 0 {
+		return 0
+	}
+	return k - 2
+}
+
+func newProbTable(rows, cols int) [][]Prob {
+	t := make([][]Prob, rows)
+	for i := range t {
+		t[i] = make([]Prob, cols)
+		for j := range t[i] {
+			t[i][j] = MinProb
+		}
+	}
+	return t
+}
+
+func newViterbiTable(rows, cols int) [][]viterbiCell {
+	t := make([][]viterbiCell, rows)
+	for i := range t {
+		t[i] = make([]viterbiCell, cols)
+		for j := range t[i] {
+			t[i][j] = viterbiCell{score: MinProb, fromRow: -1}
+		}
+	}
+	return t
+}
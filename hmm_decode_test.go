@@ -0,0 +1,170 @@
+package seq
+
+import (
+	"math"
+	"testing"
+)
+
+// twoNodeHMM builds a tiny, fully deterministic 2-node HMM over the
+// alphabet {A, B} in which every transition other than M->M is MinProb, so
+// there is exactly one possible Plan7 state path for any 2-residue
+// sequence: Match, Match.
+func twoNodeHMM() *HMM {
+	alphabet := Alphabet{'A', 'B'}
+
+	onlyMM := TProbs{MM: 0, MI: MinProb, MD: MinProb, IM: MinProb, II: MinProb, DM: MinProb, DD: MinProb}
+
+	node0 := HMMNode{
+		NodeNum:     1,
+		MatEmit:     EProbs{'A': 0, 'B': 5},
+		InsEmit:     EProbs{'A': MinProb, 'B': MinProb},
+		Transitions: onlyMM,
+	}
+	node1 := HMMNode{
+		NodeNum:     2,
+		MatEmit:     EProbs{'A': 5, 'B': 0},
+		InsEmit:     EProbs{'A': MinProb, 'B': MinProb},
+		Transitions: onlyMM,
+	}
+	return NewHMM([]HMMNode{node0, node1}, alphabet, nil)
+}
+
+func TestViterbiSinglePath(t *testing.T) {
+	hmm := twoNodeHMM()
+	s := Sequence{Residues: []Residue{'A', 'B'}}
+
+	path, score := hmm.Viterbi(s)
+
+	wantPath := []HMMState{Match, Match}
+	if len(path) != len(wantPath) {
+		t.Fatalf("path = %v, want %v", path, wantPath)
+	}
+	for i := range wantPath {
+		if path[i] != wantPath[i] {
+			t.Errorf("path[%d] = %v, want %v", i, path[i], wantPath[i])
+		}
+	}
+	if score != 0 {
+		t.Errorf("score = %v, want 0", score)
+	}
+}
+
+func TestForwardMatchesViterbiOnSinglePath(t *testing.T) {
+	hmm := twoNodeHMM()
+	s := Sequence{Residues: []Residue{'A', 'B'}}
+
+	_, viterbiScore := hmm.Viterbi(s)
+	forwardScore := hmm.Forward(s)
+
+	if forwardScore != viterbiScore {
+		t.Errorf("Forward = %v, want %v (only one path is possible)",
+			forwardScore, viterbiScore)
+	}
+}
+
+func TestForwardNeverExceedsViterbi(t *testing.T) {
+	// With branching (an alternative, worse, path available via a
+	// non-MinProb MI/II/DM transition), Forward sums probability mass
+	// over every path, so its score (a smaller Prob is a larger
+	// probability) must be at most as large as Viterbi's best single path.
+	alphabet := Alphabet{'A', 'B'}
+	node0 := HMMNode{
+		NodeNum: 1,
+		MatEmit: EProbs{'A': 0, 'B': 3},
+		InsEmit: EProbs{'A': 2, 'B': 2},
+		Transitions: TProbs{
+			MM: 0, MI: 4, MD: MinProb,
+			IM: 1, II: 3, DM: MinProb, DD: MinProb,
+		},
+	}
+	node1 := HMMNode{
+		NodeNum: 2,
+		MatEmit: EProbs{'A': 3, 'B': 0},
+		InsEmit: EProbs{'A': 2, 'B': 2},
+		Transitions: TProbs{
+			MM: 0, MI: 4, MD: MinProb,
+			IM: 1, II: MinProb, DM: MinProb, DD: MinProb,
+		},
+	}
+	hmm := NewHMM([]HMMNode{node0, node1}, alphabet, nil)
+	// "ABB" can be generated as M1 I1 M2 or as M1 M2 I2, so Forward sums
+	// two paths here.
+	s := Sequence{Residues: []Residue{'A', 'B', 'B'}}
+
+	_, viterbiScore := hmm.Viterbi(s)
+	forwardScore := hmm.Forward(s)
+
+	if forwardScore.Less(viterbiScore) {
+		t.Errorf("Forward = %v is a worse (less probable) score than "+
+			"Viterbi = %v; Forward must sum in at least as much "+
+			"probability mass as the single best path", forwardScore, viterbiScore)
+	}
+}
+
+func TestForwardBackwardPosteriorsSumNearOne(t *testing.T) {
+	hmm := twoNodeHMM()
+	s := Sequence{Residues: []Residue{'A', 'B'}}
+
+	posteriors, ll := hmm.ForwardBackward(s)
+
+	if math.IsNaN(float64(ll)) {
+		t.Fatalf("ll is NaN")
+	}
+	if ll.IsMin() {
+		t.Fatalf("ll is MinProb; expected a real likelihood")
+	}
+	if ll != 0 {
+		t.Errorf("ll = %v, want 0 (this HMM has exactly one possible path)", ll)
+	}
+	if len(posteriors) != s.Len() {
+		t.Fatalf("got %d positions of posteriors, want %d", len(posteriors), s.Len())
+	}
+
+	// This HMM has exactly one possible path, so each position's posterior
+	// mass should sit entirely on the one node that emits it: probability 1
+	// (Prob 0) at that node, and MinProb everywhere else.
+	for j, dist := range posteriors {
+		if len(dist) != len(hmm.Nodes) {
+			t.Fatalf("position %d: got %d node posteriors, want %d",
+				j, len(dist), len(hmm.Nodes))
+		}
+		for k, p := range dist {
+			if math.IsNaN(float64(p)) {
+				t.Fatalf("position %d, node %d: posterior is NaN", j, k)
+			}
+			if k == j {
+				if p != 0 {
+					t.Errorf("position %d: posterior at its own node %d = %v, want 0", j, k, p)
+				}
+				continue
+			}
+			if !p.IsMin() {
+				t.Errorf("position %d: posterior at node %d = %v, want MinProb", j, k, p)
+			}
+		}
+	}
+}
+
+func TestForwardBackwardNoNaNWithUnreachablePredecessors(t *testing.T) {
+	// twoNodeHMM's only non-MinProb transitions are MM, so every DP cell
+	// that isn't on the single valid path is built from two MinProb
+	// operands; this must not overflow to NaN anywhere along the way.
+	hmm := twoNodeHMM()
+	s := Sequence{Residues: []Residue{'A', 'B'}}
+
+	posteriors, ll := hmm.ForwardBackward(s)
+	if math.IsNaN(float64(ll)) {
+		t.Fatalf("ll is NaN")
+	}
+	for j, dist := range posteriors {
+		for k, p := range dist {
+			if math.IsNaN(float64(p)) {
+				t.Errorf("posteriors[%d][%d] is NaN", j, k)
+			}
+		}
+	}
+
+	if forward := hmm.Forward(s); math.IsNaN(float64(forward)) {
+		t.Errorf("Forward = NaN")
+	}
+}
@@ -0,0 +1,161 @@
+package hmmio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/BergerLab/seq"
+)
+
+// ReadHHM parses a profile HMM in HHsuite's hhm format from r.
+//
+// Unlike HMMER3, hhm files carry an explicit NULL line giving the
+// background amino acid frequencies (populating hmm.Null), and, per
+// HHsuite convention, every node's insertion emissions are taken directly
+// from that NULL model rather than from the file.
+func ReadHHM(r io.Reader) (*seq.HMM, error) {
+	ls := newLineScanner(bufio.NewReader(r))
+
+	leng := -1
+	var alphabet []seq.Residue
+	var null seq.EProbs
+headerLoop:
+	for {
+		line, ok := ls.next()
+		if !ok {
+			return nil, fmt.Errorf("hmmio: unexpected EOF in HHM header")
+		}
+		switch {
+		case strings.HasPrefix(line, "LENG"):
+			n, err := parseIntField(line)
+			if err != nil {
+				return nil, err
+			}
+			leng = n
+		case strings.HasPrefix(line, "NULL"):
+			fields := strings.Fields(line)
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("hmmio: malformed NULL line: %q", line)
+			}
+			// The alphabet used by an hhm file's NULL/emission lines is
+			// the standard 20 amino acids in HHsuite's fixed order; we
+			// recover the count from the NULL line itself.
+			alphabet = append(alphabet[:0], hhsuiteAlphabet[:len(fields)-1]...)
+			p, err := parseProbs(fields[1:], alphabet)
+			if err != nil {
+				return nil, err
+			}
+			null = p
+		case strings.HasPrefix(line, "HMM "), line == "HMM":
+			// The HMM header line in hhm files echoes the same residue
+			// order as the NULL line; nothing further to extract.
+			if _, ok := ls.next(); !ok { // m->m m->i ... label line
+				return nil, fmt.Errorf("hmmio: unexpected EOF after HMM header")
+			}
+			break headerLoop
+		}
+	}
+	if leng < 0 {
+		return nil, fmt.Errorf("hmmio: missing LENG field in HHM header")
+	}
+	if null == nil {
+		return nil, fmt.Errorf("hmmio: missing NULL line in HHM header")
+	}
+
+	nodes := make([]seq.HMMNode, leng)
+	for i := 0; i < leng; i++ {
+		matLine, ok := ls.next()
+		if !ok {
+			return nil, fmt.Errorf("hmmio: unexpected EOF reading node %d", i+1)
+		}
+		matFields := strings.Fields(matLine)
+		if len(matFields) < 2+len(alphabet) {
+			return nil, fmt.Errorf("hmmio: malformed match line for node %d", i+1)
+		}
+		// matFields[0] is the node's consensus residue and matFields[1] is
+		// its node number (see WriteHHM's "%c %-4d " prefix); the emissions
+		// themselves start at matFields[2].
+		matEmit, err := parseProbs(matFields[2:], alphabet)
+		if err != nil {
+			return nil, err
+		}
+
+		// Consume the insert emission line (present in the file), but
+		// per HHsuite convention every node's insertion emissions are
+		// the NULL model, not whatever was written on this line.
+		if _, ok := ls.next(); !ok {
+			return nil, fmt.Errorf("hmmio: unexpected EOF reading node %d insert line", i+1)
+		}
+		insEmit := make(seq.EProbs, len(null))
+		for r, p := range null {
+			insEmit[r] = p
+		}
+
+		transLine, ok := ls.next()
+		if !ok {
+			return nil, fmt.Errorf("hmmio: unexpected EOF reading node %d transition line", i+1)
+		}
+		trans, err := parseTransitions(strings.Fields(transLine))
+		if err != nil {
+			return nil, err
+		}
+
+		nodes[i] = seq.HMMNode{
+			Residue:     seq.Residue(matFields[0][0]),
+			NodeNum:     i + 1,
+			MatEmit:     matEmit,
+			InsEmit:     insEmit,
+			Transitions: trans,
+		}
+	}
+
+	hmm := seq.NewHMM(nodes, alphabet, null)
+	for i := range hmm.Nodes {
+		hmm.Nodes[i].HMM = hmm
+	}
+
+	for {
+		line, ok := ls.next()
+		if !ok || strings.TrimSpace(line) == "//" {
+			break
+		}
+	}
+	return hmm, nil
+}
+
+// WriteHHM serializes hmm in HHsuite's hhm format to w.
+func WriteHHM(w io.Writer, hmm *seq.HMM) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "HHsuite 1.5")
+	fmt.Fprintln(bw, "NAME  -")
+	fmt.Fprintf(bw, "LENG  %d\n", len(hmm.Nodes))
+	bw.WriteString("NULL   ")
+	writeProbs(bw, hmm.Null, hmm.Alphabet)
+	bw.WriteString("HMM   ")
+	for _, r := range hmm.Alphabet {
+		bw.WriteString(" ")
+		bw.WriteByte(byte(r))
+	}
+	bw.WriteByte('\n')
+	fmt.Fprintln(bw, "       m->m     m->i     m->d     i->m     i->i     d->m     d->d")
+
+	for _, node := range hmm.Nodes {
+		fmt.Fprintf(bw, "%c %-4d ", byte(node.Residue), node.NodeNum)
+		writeProbs(bw, node.MatEmit, hmm.Alphabet)
+		bw.WriteString("      ")
+		writeProbs(bw, hmm.Null, hmm.Alphabet)
+		bw.WriteString("      ")
+		writeTransitions(bw, node.Transitions)
+	}
+	fmt.Fprintln(bw, "//")
+	return bw.Flush()
+}
+
+// hhsuiteAlphabet is the fixed residue order used by HHsuite's hhm format.
+var hhsuiteAlphabet = []seq.Residue{
+	'A', 'R', 'N', 'D', 'C', 'Q', 'E', 'G', 'H', 'I',
+	'L', 'K', 'M', 'F', 'P', 'S', 'T', 'W', 'Y', 'V',
+}
@@ -0,0 +1,193 @@
+package hmmio
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/BergerLab/seq"
+)
+
+// ReadHMMER3 parses a profile HMM in HMMER3/f format (as produced by
+// hmmbuild) from r.
+//
+// It reads the NAME/LENG/ALPH header fields, the HMM header line (which
+// gives the residue order used by every emission line that follows), the
+// COMPO line, and then one MAT/INSERT/TRANSITION triplet per node. When a
+// node's MAT line has at least three fields after the emissions and the
+// first three all parse as probabilities, they populate HMMNode.NeffM/NeffI/
+// NeffD; otherwise those fields are left at their zero value.
+func ReadHMMER3(r io.Reader) (*seq.HMM, error) {
+	ls := newLineScanner(bufio.NewReader(r))
+
+	leng := -1
+	var alphabet []seq.Residue
+	for {
+		line, ok := ls.next()
+		if !ok {
+			return nil, fmt.Errorf("hmmio: unexpected EOF in HMMER3 header")
+		}
+		if strings.HasPrefix(line, "HMMER3") {
+			continue
+		}
+		if strings.HasPrefix(line, "LENG") {
+			n, err := parseIntField(line)
+			if err != nil {
+				return nil, err
+			}
+			leng = n
+			continue
+		}
+		if strings.HasPrefix(line, "HMM ") || line == "HMM" {
+			fields := strings.Fields(line)
+			for _, f := range fields[1:] {
+				alphabet = append(alphabet, seq.Residue(f[0]))
+			}
+			break
+		}
+		// NAME, ALPH, and any other header keys are accepted but not
+		// otherwise interpreted.
+	}
+	if leng < 0 {
+		return nil, fmt.Errorf("hmmio: missing LENG field in HMMER3 header")
+	}
+	if len(alphabet) == 0 {
+		return nil, fmt.Errorf("hmmio: missing HMM residue order line")
+	}
+
+	// The line following the HMM header gives the transition field names
+	// (m->m m->i ...); it's purely documentation and is skipped.
+	if _, ok := ls.next(); !ok {
+		return nil, fmt.Errorf("hmmio: unexpected EOF after HMM header")
+	}
+
+	// COMPO line: average match emissions, followed by its own insert and
+	// transition lines. We don't need the average composition itself, but
+	// must consume all three lines to stay in sync with the node records.
+	composLine, ok := ls.next()
+	if !ok {
+		return nil, fmt.Errorf("hmmio: unexpected EOF reading COMPO line")
+	}
+	if !strings.HasPrefix(strings.TrimSpace(composLine), "COMPO") {
+		return nil, fmt.Errorf("hmmio: expected COMPO line, got %q", composLine)
+	}
+	if _, ok := ls.next(); !ok { // insert emissions for node 0
+		return nil, fmt.Errorf("hmmio: unexpected EOF reading COMPO insert line")
+	}
+	if _, ok := ls.next(); !ok { // transitions for node 0
+		return nil, fmt.Errorf("hmmio: unexpected EOF reading COMPO transition line")
+	}
+
+	nodes := make([]seq.HMMNode, leng)
+	for i := 0; i < leng; i++ {
+		matLine, ok := ls.next()
+		if !ok {
+			return nil, fmt.Errorf("hmmio: unexpected EOF reading node %d", i+1)
+		}
+		matFields := strings.Fields(matLine)
+		if len(matFields) < 1+len(alphabet) {
+			return nil, fmt.Errorf("hmmio: malformed match line for node %d", i+1)
+		}
+		matEmit, err := parseProbs(matFields[1:], alphabet)
+		if err != nil {
+			return nil, err
+		}
+
+		insLine, ok := ls.next()
+		if !ok {
+			return nil, fmt.Errorf("hmmio: unexpected EOF reading node %d insert line", i+1)
+		}
+		insEmit, err := parseProbs(strings.Fields(insLine), alphabet)
+		if err != nil {
+			return nil, err
+		}
+
+		transLine, ok := ls.next()
+		if !ok {
+			return nil, fmt.Errorf("hmmio: unexpected EOF reading node %d transition line", i+1)
+		}
+		trans, err := parseTransitions(strings.Fields(transLine))
+		if err != nil {
+			return nil, err
+		}
+
+		node := seq.HMMNode{
+			NodeNum:     i + 1,
+			MatEmit:     matEmit,
+			InsEmit:     insEmit,
+			Transitions: trans,
+		}
+		// Unlike hhm, HMMER3/f's MAT line carries no per-node consensus
+		// residue column, so node.Residue is left at its zero value rather
+		// than guessing.
+		// The three fields right after the emissions are taken as per-state
+		// effective counts only if all three parse as probabilities; any
+		// other trailing columns (such as MAP/consensus/RF/CS annotations)
+		// are ignored.
+		if extra := matFields[1+len(alphabet):]; len(extra) >= 3 {
+			neffM, errM := seq.NewProb(extra[0])
+			neffI, errI := seq.NewProb(extra[1])
+			neffD, errD := seq.NewProb(extra[2])
+			if errM == nil && errI == nil && errD == nil {
+				node.NeffM, node.NeffI, node.NeffD = neffM, neffI, neffD
+			}
+		}
+		nodes[i] = node
+	}
+
+	hmm := seq.NewHMM(nodes, alphabet, nil)
+	for i := range hmm.Nodes {
+		hmm.Nodes[i].HMM = hmm
+	}
+
+	// Consume through the "//" record terminator, if present.
+	for {
+		line, ok := ls.next()
+		if !ok || strings.TrimSpace(line) == "//" {
+			break
+		}
+	}
+	return hmm, nil
+}
+
+// WriteHMMER3 serializes hmm in HMMER3/f format to w. The NAME field is
+// written as the placeholder "-"; callers that need a populated NAME should post-process the
+// written bytes (the format has no home for it on the HMM type itself).
+func WriteHMMER3(w io.Writer, hmm *seq.HMM) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "HMMER3/f [seq]")
+	fmt.Fprintln(bw, "NAME  -")
+	fmt.Fprintf(bw, "LENG  %d\n", len(hmm.Nodes))
+	fmt.Fprintln(bw, "ALPH  amino")
+	bw.WriteString("HMM ")
+	for _, r := range hmm.Alphabet {
+		bw.WriteString("      ")
+		bw.WriteByte(byte(r))
+	}
+	bw.WriteByte('\n')
+	fmt.Fprintln(bw, "            m->m     m->i     m->d     i->m     i->i     d->m     d->d")
+
+	zeroEmit := make(seq.EProbs, len(hmm.Alphabet))
+	for _, r := range hmm.Alphabet {
+		zeroEmit[r] = seq.MinProb
+	}
+	bw.WriteString("  COMPO ")
+	writeProbs(bw, zeroEmit, hmm.Alphabet)
+	bw.WriteString("        ")
+	writeProbs(bw, zeroEmit, hmm.Alphabet)
+	bw.WriteString("        ")
+	writeTransitions(bw, seq.TProbs{})
+
+	for _, node := range hmm.Nodes {
+		fmt.Fprintf(bw, "  %-4d ", node.NodeNum)
+		writeProbs(bw, node.MatEmit, hmm.Alphabet)
+		bw.WriteString("        ")
+		writeProbs(bw, node.InsEmit, hmm.Alphabet)
+		bw.WriteString("        ")
+		writeTransitions(bw, node.Transitions)
+	}
+	fmt.Fprintln(bw, "//")
+	return bw.Flush()
+}
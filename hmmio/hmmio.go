@@ -0,0 +1,120 @@
+// Package hmmio reads and writes the HMM file formats used by the HMMER and
+// HHsuite tool suites, converting between them and the seq package's HMM
+// type.
+package hmmio
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/BergerLab/seq"
+)
+
+// transitionOrder is the fixed column order that both HMMER3 and HHsuite
+// use for the 7-field Plan7 transition line.
+var transitionOrder = [7]func(*seq.TProbs) *seq.Prob{
+	func(t *seq.TProbs) *seq.Prob { return &t.MM },
+	func(t *seq.TProbs) *seq.Prob { return &t.MI },
+	func(t *seq.TProbs) *seq.Prob { return &t.MD },
+	func(t *seq.TProbs) *seq.Prob { return &t.IM },
+	func(t *seq.TProbs) *seq.Prob { return &t.II },
+	func(t *seq.TProbs) *seq.Prob { return &t.DM },
+	func(t *seq.TProbs) *seq.Prob { return &t.DD },
+}
+
+// parseProbs parses count probability fields (in the order given by
+// alphabet) from whitespace separated fstrs, each of which is either a
+// floating point number or "*" (see seq.NewProb).
+func parseProbs(fstrs []string, alphabet []seq.Residue) (seq.EProbs, error) {
+	if len(fstrs) < len(alphabet) {
+		return nil, fmt.Errorf(
+			"expected %d emission fields but got %d", len(alphabet), len(fstrs))
+	}
+	ep := make(seq.EProbs, len(alphabet))
+	for i, r := range alphabet {
+		p, err := seq.NewProb(fstrs[i])
+		if err != nil {
+			return nil, err
+		}
+		ep[r] = p
+	}
+	return ep, nil
+}
+
+// parseTransitions parses the 7 Plan7 transition fields, in the canonical
+// MM MI MD IM II DM DD order, from fstrs.
+func parseTransitions(fstrs []string) (seq.TProbs, error) {
+	var t seq.TProbs
+	if len(fstrs) < len(transitionOrder) {
+		return t, fmt.Errorf(
+			"expected %d transition fields but got %d",
+			len(transitionOrder), len(fstrs))
+	}
+	for i, field := range transitionOrder {
+		p, err := seq.NewProb(fstrs[i])
+		if err != nil {
+			return t, err
+		}
+		*field(&t) = p
+	}
+	return t, nil
+}
+
+// writeProbs writes one emission probability per residue in alphabet,
+// space separated, using Prob's String method (which emits "*" for
+// MinProb).
+func writeProbs(w *bufio.Writer, ep seq.EProbs, alphabet []seq.Residue) {
+	for i, r := range alphabet {
+		if i > 0 {
+			w.WriteByte(' ')
+		}
+		w.WriteString(ep[r].String())
+	}
+	w.WriteByte('\n')
+}
+
+// writeTransitions writes the 7 Plan7 transition fields, in the canonical
+// MM MI MD IM II DM DD order.
+func writeTransitions(w *bufio.Writer, t seq.TProbs) {
+	for i, field := range transitionOrder {
+		if i > 0 {
+			w.WriteByte(' ')
+		}
+		w.WriteString(field(&t).String())
+	}
+	w.WriteByte('\n')
+}
+
+// lineScanner is a small helper around bufio.Scanner that trims blank lines
+// out of the way of the fixed-format parsers below.
+type lineScanner struct {
+	*bufio.Scanner
+}
+
+func newLineScanner(r *bufio.Reader) *lineScanner {
+	return &lineScanner{bufio.NewScanner(r)}
+}
+
+// next returns the next non-empty line, or "", false at EOF.
+func (ls *lineScanner) next() (string, bool) {
+	for ls.Scan() {
+		line := strings.TrimRight(ls.Text(), "\r\n")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		return line, true
+	}
+	return "", false
+}
+
+// parseIntField extracts the integer following a leading keyword, e.g.
+// "LENG  120" -> 120.
+func parseIntField(line string) (int, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return 0, fmt.Errorf("malformed field line: %q", line)
+	}
+	return strconv.Atoi(fields[1])
+}
@@ -0,0 +1,113 @@
+package hmmio
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/BergerLab/seq"
+)
+
+func testHMM() *seq.HMM {
+	alphabet := append([]seq.Residue{}, hhsuiteAlphabet...)
+
+	mkEmit := func(base float64) seq.EProbs {
+		ep := seq.NewEProbs(alphabet)
+		for i, r := range alphabet {
+			ep[r] = seq.Prob(base + float64(i)*0.01)
+		}
+		return ep
+	}
+	trans := seq.TProbs{MM: 0.1, MI: 2.0, MD: 3.5, IM: 0.2, II: 1.1, DM: 0.3, DD: 1.4}
+
+	nodes := []seq.HMMNode{
+		{NodeNum: 1, Residue: 'A', MatEmit: mkEmit(1.0), InsEmit: mkEmit(2.0), Transitions: trans},
+		{NodeNum: 2, Residue: 'R', MatEmit: mkEmit(1.5), InsEmit: mkEmit(2.5), Transitions: trans},
+		{NodeNum: 3, Residue: 'N', MatEmit: mkEmit(0.5), InsEmit: mkEmit(1.8), Transitions: trans},
+	}
+
+	null := seq.NewEProbs(alphabet)
+	for i, r := range alphabet {
+		null[r] = seq.Prob(4.0 + float64(i)*0.02)
+	}
+
+	hmm := seq.NewHMM(nodes, alphabet, null)
+	for i := range hmm.Nodes {
+		hmm.Nodes[i].HMM = hmm
+	}
+	return hmm
+}
+
+func TestHMMER3RoundTrip(t *testing.T) {
+	orig := testHMM()
+
+	var buf bytes.Buffer
+	if err := WriteHMMER3(&buf, orig); err != nil {
+		t.Fatalf("WriteHMMER3: %s", err)
+	}
+
+	got, err := ReadHMMER3(&buf)
+	if err != nil {
+		t.Fatalf("ReadHMMER3: %s", err)
+	}
+
+	if len(got.Nodes) != len(orig.Nodes) {
+		t.Fatalf("got %d nodes, want %d", len(got.Nodes), len(orig.Nodes))
+	}
+	for i := range orig.Nodes {
+		want, have := orig.Nodes[i], got.Nodes[i]
+		// Unlike hhm, HMMER3/f carries no per-node consensus residue
+		// column, so it can't round-trip: the parsed node must be left at
+		// the zero value rather than fabricating one.
+		if have.Residue != seq.Residue(0) {
+			t.Errorf("node %d: Residue = %c, want the zero value", i, have.Residue)
+		}
+		if !reflect.DeepEqual(want.MatEmit, have.MatEmit) {
+			t.Errorf("node %d: MatEmit = %v, want %v", i, have.MatEmit, want.MatEmit)
+		}
+		if !reflect.DeepEqual(want.InsEmit, have.InsEmit) {
+			t.Errorf("node %d: InsEmit = %v, want %v", i, have.InsEmit, want.InsEmit)
+		}
+		if want.Transitions != have.Transitions {
+			t.Errorf("node %d: Transitions = %+v, want %+v", i, have.Transitions, want.Transitions)
+		}
+	}
+}
+
+func TestHHMRoundTrip(t *testing.T) {
+	orig := testHMM()
+
+	var buf bytes.Buffer
+	if err := WriteHHM(&buf, orig); err != nil {
+		t.Fatalf("WriteHHM: %s", err)
+	}
+
+	got, err := ReadHHM(&buf)
+	if err != nil {
+		t.Fatalf("ReadHHM: %s", err)
+	}
+
+	if !reflect.DeepEqual(got.Null, orig.Null) {
+		t.Errorf("Null = %v, want %v", got.Null, orig.Null)
+	}
+	if len(got.Nodes) != len(orig.Nodes) {
+		t.Fatalf("got %d nodes, want %d", len(got.Nodes), len(orig.Nodes))
+	}
+	for i := range orig.Nodes {
+		want, have := orig.Nodes[i], got.Nodes[i]
+		if want.Residue != have.Residue {
+			t.Errorf("node %d: Residue = %c, want %c", i, have.Residue, want.Residue)
+		}
+		if !reflect.DeepEqual(want.MatEmit, have.MatEmit) {
+			t.Errorf("node %d: MatEmit = %v, want %v", i, have.MatEmit, want.MatEmit)
+		}
+		// HHsuite convention: insertion emissions always come from the
+		// NULL model, not whatever the original node's InsEmit was.
+		if !reflect.DeepEqual(have.InsEmit, orig.Null) {
+			t.Errorf("node %d: InsEmit = %v, want NULL model %v", i, have.InsEmit, orig.Null)
+		}
+		if want.Transitions != have.Transitions {
+			t.Errorf("node %d: Transitions = %+v, want %+v", i, have.Transitions, want.Transitions)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package seq
+
+import "math"
+
+// Prior computes pseudocount-smoothed residue probability estimates for a
+// single profile column. Given the raw observed counts for a column, it
+// fills out with a probability estimate for every residue in column (the
+// values in out should sum to approximately 1).
+//
+// Priors exist because FrequencyProfile.Profile collapses a column to
+// MinProb whenever a residue (or the null model) was never observed, which
+// makes profiles built from only a handful of sequences degenerate. A Prior
+// mixes in outside information—a flat pseudocount, the null model, or a
+// Dirichlet mixture—so that sparsely observed columns still yield sensible
+// probability estimates.
+type Prior interface {
+	AddPseudocounts(column map[Residue]int, out map[Residue]float64)
+}
+
+// LaplacePrior is the simplest possible pseudocount scheme: it adds a flat
+// Alpha to every residue's observed count before normalizing.
+type LaplacePrior struct {
+	Alpha float64
+}
+
+// AddPseudocounts implements the Prior interface.
+func (lp LaplacePrior) AddPseudocounts(
+	column map[Residue]int,
+	out map[Residue]float64,
+) {
+	tot := 0.0
+	for _, n := range column {
+		tot += float64(n) + lp.Alpha
+	}
+	if tot == 0 {
+		return
+	}
+	for r, n := range column {
+		out[r] = (float64(n) + lp.Alpha) / tot
+	}
+}
+
+// BackgroundPrior mixes a column's observed frequencies with the null
+// model's background frequencies, at a strength controlled by Beta: larger
+// values of Beta pull sparsely observed columns closer to the background,
+// while columns with many observations are barely affected.
+//
+// Use NewBackgroundPrior to construct one from a null FrequencyProfile.
+type BackgroundPrior struct {
+	Beta float64
+
+	null map[Residue]float64
+}
+
+// NewBackgroundPrior builds a BackgroundPrior from a null model tabulated as
+// a single-column FrequencyProfile (see NewNullProfile).
+func NewBackgroundPrior(beta float64, null *FrequencyProfile) *BackgroundPrior {
+	if null.Len() != 1 {
+		panic("null model must have exactly 1 column")
+	}
+	tot := freqTotal(null.Freqs[0])
+	probs := make(map[Residue]float64, len(null.Freqs[0]))
+	for r, n := range null.Freqs[0] {
+		probs[r] = float64(n) / float64(tot)
+	}
+	return &BackgroundPrior{Beta: beta, null: probs}
+}
+
+// AddPseudocounts implements the Prior interface.
+func (bp *BackgroundPrior) AddPseudocounts(
+	column map[Residue]int,
+	out map[Residue]float64,
+) {
+	n := float64(freqTotal(column))
+	for r, c := range column {
+		out[r] = (float64(c) + bp.Beta*bp.null[r]) / (n + bp.Beta)
+	}
+}
+
+// DirichletComponent is a single component of a Dirichlet mixture prior: a
+// mixture weight and a vector of pseudocounts (one per residue) describing
+// a typical amino acid composition (e.g., hydrophobic core, charged
+// surface, and so on).
+type DirichletComponent struct {
+	Weight float64
+	Alpha  map[Residue]float64
+}
+
+// DirichletMixturePrior implements the HMMER/SAM-style multi-component
+// amino acid Dirichlet mixture prior. Rather than a single flat pseudocount,
+// each observed column is treated as a mixture of a handful of typical
+// amino acid distributions (Components); the posterior weight of each
+// component given the column's observed counts is computed via the
+// multivariate Beta function, and the final smoothed probabilities are the
+// weight-averaged posterior means across components.
+//
+// See Sjolander et al., "Dirichlet Mixtures: A Method for Improved
+// Detection of Weak but Significant Protein Sequence Homology" (1996).
+type DirichletMixturePrior struct {
+	Components []DirichletComponent
+}
+
+// AddPseudocounts implements the Prior interface.
+func (dm *DirichletMixturePrior) AddPseudocounts(
+	column map[Residue]int,
+	out map[Residue]float64,
+) {
+	n := 0.0
+	for _, c := range column {
+		n += float64(c)
+	}
+
+	// logWeight[k] is proportional to log P(component k | column), up to
+	// an additive constant common to every component.
+	logWeight := make([]float64, len(dm.Components))
+	maxLogWeight := math.Inf(-1)
+	for k, comp := range dm.Components {
+		logWeight[k] = math.Log(comp.Weight) +
+			logMultiBeta(column, comp.Alpha) -
+			logMultiBeta(nil, comp.Alpha)
+		if logWeight[k] > maxLogWeight {
+			maxLogWeight = logWeight[k]
+		}
+	}
+
+	// Normalize the component posteriors with the standard softmax
+	// max-shift trick.
+	norm := 0.0
+	postWeight := make([]float64, len(dm.Components))
+	for k := range dm.Components {
+		postWeight[k] = math.Exp(logWeight[k] - maxLogWeight)
+		norm += postWeight[k]
+	}
+	for k := range postWeight {
+		postWeight[k] /= norm
+	}
+
+	for r := range column {
+		p := 0.0
+		for k, comp := range dm.Components {
+			alphaSum := 0.0
+			for _, a := range comp.Alpha {
+				alphaSum += a
+			}
+			p += postWeight[k] * (float64(column[r]) + comp.Alpha[r]) / (n + alphaSum)
+		}
+		out[r] = p
+	}
+}
+
+// logMultiBeta computes the log of the multivariate Beta function evaluated
+// at alpha+column, i.e. log B(alpha + column), using lgamma so that it
+// remains numerically stable for the large counts that accumulate over many
+// sequences. column may be nil, in which case it is treated as all zeroes
+// (i.e., this computes log B(alpha)).
+func logMultiBeta(column map[Residue]int, alpha map[Residue]float64) float64 {
+	sum := 0.0
+	total := 0.0
+	for r, a := range alpha {
+		x := a
+		if column != nil {
+			x += float64(column[r])
+		}
+		lg, _ := math.Lgamma(x)
+		sum += lg
+		total += x
+	}
+	lgTotal, _ := math.Lgamma(total)
+	return sum - lgTotal
+}
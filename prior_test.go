@@ -0,0 +1,128 @@
+package seq
+
+import (
+	"math"
+	"testing"
+)
+
+func approxEqual(t *testing.T, name string, got, want, tol float64) {
+	t.Helper()
+	if math.Abs(got-want) > tol {
+		t.Errorf("%s = %v, want %v (+/- %v)", name, got, want, tol)
+	}
+}
+
+func TestLaplacePriorUniformOnEmptyColumn(t *testing.T) {
+	lp := LaplacePrior{Alpha: 1}
+	column := map[Residue]int{'A': 0, 'C': 0}
+	out := make(map[Residue]float64)
+
+	lp.AddPseudocounts(column, out)
+
+	approxEqual(t, "out['A']", out['A'], 0.5, 1e-9)
+	approxEqual(t, "out['C']", out['C'], 0.5, 1e-9)
+}
+
+func TestLaplacePriorSkewedByCounts(t *testing.T) {
+	lp := LaplacePrior{Alpha: 1}
+	column := map[Residue]int{'A': 9, 'C': 0}
+	out := make(map[Residue]float64)
+
+	lp.AddPseudocounts(column, out)
+
+	// (9+1)/(9+0+2) == 10/11, (0+1)/11 == 1/11
+	approxEqual(t, "out['A']", out['A'], 10.0/11.0, 1e-9)
+	approxEqual(t, "out['C']", out['C'], 1.0/11.0, 1e-9)
+}
+
+func TestBackgroundPriorPullsSparseColumnTowardNull(t *testing.T) {
+	null := NewFrequencyProfile(1)
+	null.Freqs[0]['A'] = 3
+	null.Freqs[0]['C'] = 1
+
+	bp := NewBackgroundPrior(4, null)
+	column := map[Residue]int{'A': 0, 'C': 0}
+	out := make(map[Residue]float64)
+
+	bp.AddPseudocounts(column, out)
+
+	// With n == 0 observed, the posterior must equal the null exactly.
+	approxEqual(t, "out['A']", out['A'], 0.75, 1e-9)
+	approxEqual(t, "out['C']", out['C'], 0.25, 1e-9)
+}
+
+func TestBackgroundPriorConvergesWithManyObservations(t *testing.T) {
+	null := NewFrequencyProfile(1)
+	null.Freqs[0]['A'] = 1
+	null.Freqs[0]['C'] = 1
+
+	bp := NewBackgroundPrior(1, null)
+	column := map[Residue]int{'A': 999, 'C': 1}
+	out := make(map[Residue]float64)
+
+	bp.AddPseudocounts(column, out)
+
+	// With 1000 real observations against a pseudocount strength of 1, the
+	// posterior should sit very close to the observed frequency.
+	approxEqual(t, "out['A']", out['A'], 999.5/1001.0, 1e-6)
+}
+
+func TestLogMultiBetaSymmetric(t *testing.T) {
+	alpha := map[Residue]float64{'A': 2, 'C': 2}
+	column := map[Residue]int{'A': 3, 'C': 3}
+
+	// Swapping two residues that have identical alpha and identical counts
+	// must leave logMultiBeta unchanged.
+	alphaSwapped := map[Residue]float64{'A': 2, 'C': 2}
+	columnSwapped := map[Residue]int{'A': 3, 'C': 3}
+
+	got := logMultiBeta(column, alpha)
+	want := logMultiBeta(columnSwapped, alphaSwapped)
+	approxEqual(t, "logMultiBeta", got, want, 1e-12)
+}
+
+func TestLogMultiBetaNilColumnIsAllZeroes(t *testing.T) {
+	alpha := map[Residue]float64{'A': 1, 'C': 1}
+	zeroColumn := map[Residue]int{'A': 0, 'C': 0}
+
+	got := logMultiBeta(nil, alpha)
+	want := logMultiBeta(zeroColumn, alpha)
+	approxEqual(t, "logMultiBeta(nil, alpha)", got, want, 1e-12)
+}
+
+func TestDirichletMixturePriorMatchesDominantComponent(t *testing.T) {
+	// A column with an overwhelming A-count should pick out the component
+	// whose alpha is concentrated on A, nearly ignoring the other.
+	mix := &DirichletMixturePrior{
+		Components: []DirichletComponent{
+			{Weight: 0.5, Alpha: map[Residue]float64{'A': 10, 'C': 0.1}},
+			{Weight: 0.5, Alpha: map[Residue]float64{'A': 0.1, 'C': 10}},
+		},
+	}
+	column := map[Residue]int{'A': 50, 'C': 0}
+	out := make(map[Residue]float64)
+
+	mix.AddPseudocounts(column, out)
+
+	if out['A'] < 0.9 {
+		t.Errorf("out['A'] = %v, want close to 1 (dominant component should win)", out['A'])
+	}
+}
+
+func TestDirichletMixturePriorWithSingleComponentMatchesItsAlpha(t *testing.T) {
+	// With only one component, the mixture posterior has nothing to choose
+	// between, so it reduces to that component's own Bayesian update.
+	mix := &DirichletMixturePrior{
+		Components: []DirichletComponent{
+			{Weight: 1, Alpha: map[Residue]float64{'A': 1, 'C': 3}},
+		},
+	}
+	column := map[Residue]int{'A': 1, 'C': 1}
+	out := make(map[Residue]float64)
+
+	mix.AddPseudocounts(column, out)
+
+	// (1+1)/(2+4) == 1/3, (1+3)/(2+4) == 2/3
+	approxEqual(t, "out['A']", out['A'], 1.0/3.0, 1e-9)
+	approxEqual(t, "out['C']", out['C'], 2.0/3.0, 1e-9)
+}
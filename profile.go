@@ -180,6 +180,54 @@ func (fp *FrequencyProfile) Profile(null *FrequencyProfile) *Profile {
 	return p
 }
 
+// ProfileWithPrior converts a raw frequency profile to a log-odds Profile,
+// just like Profile, except that each column's residue probabilities are
+// smoothed with prior before being converted to log-odds. This avoids the
+// MinProb collapse that Profile exhibits whenever a column hasn't observed
+// some residue, which makes profiles built from only a handful of sequences
+// degenerate. A column emits MinProb for a residue when prior assigns that
+// residue zero probability, and also when the null model has a zero count
+// for it; the null model itself is not smoothed.
+func (fp *FrequencyProfile) ProfileWithPrior(
+	null *FrequencyProfile,
+	prior Prior,
+) *Profile {
+	if null.Len() != 1 {
+		panic(fmt.Sprintf("null model has %d columns; should have 1",
+			null.Len()))
+	}
+	if !fp.Alphabet.Equals(null.Alphabet) {
+		panic(fmt.Sprintf("freq profile alphabet '%s' is not equal to "+
+			"null profile alphabet '%s'.", fp.Alphabet, null.Alphabet))
+	}
+	p := NewProfileAlphabet(fp.Len(), fp.Alphabet)
+
+	nulltot := freqTotal(null.Freqs[0])
+	nullemit := make(map[Residue]float64, fp.Alphabet.Len())
+	for _, residue := range null.Alphabet {
+		nullemit[residue] = float64(null.Freqs[0][residue]) / float64(nulltot)
+	}
+
+	smoothed := make(map[Residue]float64, fp.Alphabet.Len())
+	for column := 0; column < fp.Len(); column++ {
+		for k := range smoothed {
+			delete(smoothed, k)
+		}
+		prior.AddPseudocounts(fp.Freqs[column], smoothed)
+
+		for _, residue := range fp.Alphabet {
+			prob := smoothed[residue]
+			if prob <= 0 || nullemit[residue] == 0 {
+				p.Emissions[column][residue] = MinProb
+			} else {
+				logOdds := -Prob(math.Log(prob / nullemit[residue]))
+				p.Emissions[column][residue] = logOdds
+			}
+		}
+	}
+	return p
+}
+
 // freqTotal computes the total frequency in a single column.
 func freqTotal(column map[Residue]int) int {
 	tot := 0
@@ -0,0 +1,244 @@
+package seq
+
+import (
+	"fmt"
+	"math"
+)
+
+// AlignOpts configures ProfileProfileAlign.
+type AlignOpts struct {
+	// Null gives the background residue probabilities shared by both
+	// profiles being aligned (see Profile.MatchScore and
+	// ProfileProfileAlign for how it's used).
+	Null EProbs
+
+	// Local, when true, produces a Smith-Waterman-style local alignment
+	// (the best scoring sub-alignment) instead of requiring both
+	// profiles to be aligned end to end.
+	Local bool
+
+	// GapOpen and GapExtend are affine gap penalties, charged (as
+	// negative-log-odds costs, so larger values are harsher penalties)
+	// for opening a gap and for each column it's extended by,
+	// respectively.
+	GapOpen, GapExtend Prob
+}
+
+// ColumnPair is a single aligned pair of columns (by index) produced by
+// ProfileProfileAlign, along with that column pair's individual score.
+type ColumnPair struct {
+	A, B  int
+	Score Prob
+}
+
+// ProfileAlignment is the result of aligning two profiles with
+// ProfileProfileAlign: the aligned column pairs (gaps are simply omitted
+// from Pairs) and the total score.
+type ProfileAlignment struct {
+	Pairs []ColumnPair
+	Score Prob
+}
+
+// alignMat names the three matrices of the Gotoh affine-gap recurrence.
+type alignMat int
+
+const (
+	alignM alignMat = iota
+	alignIx
+	alignIy
+)
+
+// alignCell is a single dynamic programming cell: its score and enough
+// information to trace back to the alignment's start.
+type alignCell struct {
+	score   Prob
+	mat     alignMat
+	prevI   int
+	prevJ   int
+	restart bool // true if this is a fresh local-alignment start
+}
+
+// ProfileProfileAlign aligns two log-odds profiles column-by-column,
+// HHsearch style: the per-column score between column i of a and column j
+// of b is
+//
+//	S(i,j) = log Sum_r ( p_a[i][r] * p_b[j][r] / p_null[r] )
+//
+// computed by converting each profile's stored log-odds value back to a
+// probability ratio via Prob.Ratio and re-introducing the shared background
+// opts.Null (see Profile.MatchScore for the same idea applied to a single
+// residue), and stored negated, like every Prob. The alignment itself is a
+// standard affine-gap dynamic program (Needleman-Wunsch when opts.Local is
+// false, Smith-Waterman when it's true) over these per-column scores.
+//
+// An error is returned if a and b don't share an alphabet.
+func ProfileProfileAlign(a, b *Profile, opts AlignOpts) (*ProfileAlignment, error) {
+	if !a.Alphabet.Equals(b.Alphabet) {
+		return nil, fmt.Errorf(
+			"seq: cannot align profiles with different alphabets: '%s' != '%s'",
+			a.Alphabet, b.Alphabet)
+	}
+
+	la, lb := a.Len(), b.Len()
+	m := newAlignTable(la+1, lb+1)
+	ix := newAlignTable(la+1, lb+1)
+	iy := newAlignTable(la+1, lb+1)
+
+	m[0][0] = alignCell{score: 0, prevI: -1, prevJ: -1}
+
+	for i := 1; i <= la; i++ {
+		if opts.Local {
+			m[i][0] = alignCell{score: 0, restart: true}
+		}
+		ix[i][0] = bestAlignCell(
+			alignCell{m[i-1][0].score + opts.GapOpen, alignM, i - 1, 0, false},
+			alignCell{ix[i-1][0].score + opts.GapExtend, alignIx, i - 1, 0, false},
+		)
+	}
+	for j := 1; j <= lb; j++ {
+		if opts.Local {
+			m[0][j] = alignCell{score: 0, restart: true}
+		}
+		iy[0][j] = bestAlignCell(
+			alignCell{m[0][j-1].score + opts.GapOpen, alignM, 0, j - 1, false},
+			alignCell{iy[0][j-1].score + opts.GapExtend, alignIy, 0, j - 1, false},
+		)
+	}
+
+	var bestLocal alignCell
+	bestLocal.score = MinProb
+
+	for i := 1; i <= la; i++ {
+		for j := 1; j <= lb; j++ {
+			colScore := columnPairScore(a.Emissions[i-1], b.Emissions[j-1], opts.Null)
+
+			match := bestAlignCell(
+				alignCell{m[i-1][j-1].score, alignM, i - 1, j - 1, false},
+				bestAlignCell(
+					alignCell{ix[i-1][j-1].score, alignIx, i - 1, j - 1, false},
+					alignCell{iy[i-1][j-1].score, alignIy, i - 1, j - 1, false},
+				),
+			)
+			match.score += colScore
+
+			if opts.Local && match.score >= 0 {
+				match = alignCell{score: 0, restart: true}
+			}
+			m[i][j] = match
+
+			ix[i][j] = bestAlignCell(
+				alignCell{m[i-1][j].score + opts.GapOpen, alignM, i - 1, j, false},
+				alignCell{ix[i-1][j].score + opts.GapExtend, alignIx, i - 1, j, false},
+			)
+			iy[i][j] = bestAlignCell(
+				alignCell{m[i][j-1].score + opts.GapOpen, alignM, i, j - 1, false},
+				alignCell{iy[i][j-1].score + opts.GapExtend, alignIy, i, j - 1, false},
+			)
+
+			if opts.Local && bestLocal.score.Less(m[i][j].score) {
+				bestLocal = m[i][j]
+				bestLocal.prevI, bestLocal.prevJ = i, j
+			}
+		}
+	}
+
+	tableOf := func(mat alignMat) [][]alignCell {
+		switch mat {
+		case alignIx:
+			return ix
+		case alignIy:
+			return iy
+		default:
+			return m
+		}
+	}
+
+	var i, j int
+	var mat alignMat
+	var finalScore Prob
+	if opts.Local {
+		if bestLocal.score.IsMin() {
+			return &ProfileAlignment{Score: MinProb}, nil
+		}
+		i, j, mat, finalScore = bestLocal.prevI, bestLocal.prevJ, alignM, bestLocal.score
+	} else {
+		end := bestAlignCell(
+			alignCell{m[la][lb].score, alignM, 0, 0, false},
+			bestAlignCell(
+				alignCell{ix[la][lb].score, alignIx, 0, 0, false},
+				alignCell{iy[la][lb].score, alignIy, 0, 0, false},
+			),
+		)
+		i, j, mat, finalScore = la, lb, end.mat, end.score
+	}
+
+	alignment := &ProfileAlignment{Score: finalScore}
+	for i > 0 || j > 0 {
+		cell := tableOf(mat)[i][j]
+		if opts.Local && cell.restart {
+			break
+		}
+		if mat == alignM {
+			alignment.Pairs = append(alignment.Pairs, ColumnPair{
+				A:     i - 1,
+				B:     j - 1,
+				Score: columnPairScore(a.Emissions[i-1], b.Emissions[j-1], opts.Null),
+			})
+		}
+		i, j, mat = cell.prevI, cell.prevJ, cell.mat
+	}
+
+	// Pairs were collected end-to-start; reverse them.
+	for p, q := 0, len(alignment.Pairs)-1; p < q; p, q = p+1, q-1 {
+		alignment.Pairs[p], alignment.Pairs[q] = alignment.Pairs[q], alignment.Pairs[p]
+	}
+	return alignment, nil
+}
+
+// MatchScore scores a single residue r against column col of the profile,
+// using the shared background null. Since p.Emissions already stores
+// log-odds scores relative to whatever null model the profile was built
+// with, this is the degenerate case of ProfileProfileAlign's column-pair
+// score where the "other side" is a single residue (probability 1 at r):
+// the null model cancels out of the general formula, leaving exactly the
+// profile's own stored value.
+func (p *Profile) MatchScore(col int, r Residue, null EProbs) Prob {
+	return p.Emissions[col][r]
+}
+
+// columnPairScore computes the HHsearch-style log-sum-of-odds score between
+// two profile columns a and b, given a shared background null, returned in
+// negative-log-odds space:
+//
+//	-log Sum_r ( p_a[r] * p_b[r] / p_null[r] )
+func columnPairScore(a, b, null EProbs) Prob {
+	sum := 0.0
+	for r, nullProb := range null {
+		sum += a[r].Ratio() * b[r].Ratio() * nullProb.Ratio()
+	}
+	if sum <= 0 {
+		return MinProb
+	}
+	return -Prob(math.Log(sum))
+}
+
+// bestAlignCell returns the cell with the smaller (better) score.
+func bestAlignCell(a, b alignCell) alignCell {
+	// a.score.Less(b.score) reports whether a represents a *smaller*
+	// probability than b (i.e. a is worse), so the better cell is b.
+	if a.score.Less(b.score) {
+		return b
+	}
+	return a
+}
+
+func newAlignTable(rows, cols int) [][]alignCell {
+	t := make([][]alignCell, rows)
+	for i := range t {
+		t[i] = make([]alignCell, cols)
+		for j := range t[i] {
+			t[i][j] = alignCell{score: MinProb, prevI: -1, prevJ: -1}
+		}
+	}
+	return t
+}
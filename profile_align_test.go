@@ -0,0 +1,90 @@
+package seq
+
+import "testing"
+
+func TestProfileProfileAlignIdentity(t *testing.T) {
+	alphabet := Alphabet{'A', 'C', 'G', 'T'}
+	null := NewEProbs(alphabet)
+	for _, r := range alphabet {
+		null[r] = Prob(1.386) // roughly -log(0.25)
+	}
+
+	p := NewProfileAlphabet(4, alphabet)
+	bases := []Residue{'A', 'C', 'G', 'T'}
+	for col, r := range bases {
+		for _, other := range alphabet {
+			p.Emissions[col][other] = MinProb
+		}
+		p.Emissions[col][r] = -2.0 // strongly favored residue for this column
+	}
+
+	opts := AlignOpts{Null: null, GapOpen: 5, GapExtend: 1}
+	alignment, err := ProfileProfileAlign(p, p, opts)
+	if err != nil {
+		t.Fatalf("ProfileProfileAlign: %s", err)
+	}
+
+	if len(alignment.Pairs) != p.Len() {
+		t.Fatalf("got %d aligned column pairs, want %d",
+			len(alignment.Pairs), p.Len())
+	}
+	for i, pair := range alignment.Pairs {
+		if pair.A != i || pair.B != i {
+			t.Errorf("pair %d = (%d, %d), want (%d, %d)", i, pair.A, pair.B, i, i)
+		}
+	}
+}
+
+func TestProfileProfileAlignLocal(t *testing.T) {
+	alphabet := Alphabet{'A', 'C', 'G', 'T'}
+	null := NewEProbs(alphabet)
+	for _, r := range alphabet {
+		null[r] = Prob(1.386) // roughly -log(0.25)
+	}
+
+	newStrongProfile := func(favored []Residue) *Profile {
+		p := NewProfileAlphabet(len(favored), alphabet)
+		for col, r := range favored {
+			p.Emissions[col][r] = -2.0 // strongly favored residue for this column
+		}
+		return p
+	}
+
+	// a and b only agree at columns 1 and 2; columns 0 and 3 favor
+	// mutually exclusive residues, so a global alignment has to cover them
+	// too (as MinProb column pairs or with gap penalties), while a local
+	// alignment can simply skip straight to the favorable middle.
+	a := newStrongProfile([]Residue{'A', 'C', 'G', 'T'})
+	b := newStrongProfile([]Residue{'G', 'C', 'G', 'A'})
+
+	opts := AlignOpts{Null: null, GapOpen: 5, GapExtend: 1, Local: true}
+	alignment, err := ProfileProfileAlign(a, b, opts)
+	if err != nil {
+		t.Fatalf("ProfileProfileAlign: %s", err)
+	}
+
+	wantPairs := []ColumnPair{{A: 1, B: 1}, {A: 2, B: 2}}
+	if len(alignment.Pairs) != len(wantPairs) {
+		t.Fatalf("got %d aligned column pairs, want %d: %+v",
+			len(alignment.Pairs), len(wantPairs), alignment.Pairs)
+	}
+	for i, want := range wantPairs {
+		if alignment.Pairs[i].A != want.A || alignment.Pairs[i].B != want.B {
+			t.Errorf("pair %d = (%d, %d), want (%d, %d)",
+				i, alignment.Pairs[i].A, alignment.Pairs[i].B, want.A, want.B)
+		}
+	}
+
+	// Each matching column contributes -log(exp(2)*exp(2)*0.25) ~= -2.614,
+	// and the two matching columns are adjacent so no gap penalty applies.
+	approxEqual(t, "alignment.Score", float64(alignment.Score), -5.227, 0.01)
+}
+
+func TestProfileProfileAlignMismatchedAlphabets(t *testing.T) {
+	a := NewProfileAlphabet(2, Alphabet{'A', 'C'})
+	b := NewProfileAlphabet(2, Alphabet{'A', 'C', 'G'})
+
+	if _, err := ProfileProfileAlign(a, b, AlignOpts{}); err == nil {
+		t.Fatalf("expected an error aligning profiles with different alphabets")
+	}
+}